@@ -0,0 +1,113 @@
+// Package suppress provides schema.SchemaDiffSuppressFunc helpers for
+// fields whose value is an XML document that libvirt is free to normalize
+// (attribute reordering, defaulted fields, comments) without that being a
+// meaningful change.
+package suppress
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+// XMLEquivalent is a DiffSuppressFunc that parses old and new as XML and
+// suppresses the diff if they are equivalent once normalized: attributes
+// sorted, whitespace-only character data and comments stripped, and
+// namespace prefixes resolved to their URIs rather than compared literally.
+func XMLEquivalent(k, old, new string, d *schema.ResourceData) bool {
+	if old == new {
+		return true
+	}
+
+	canonicalOld, err := canonicalizeXML(old)
+	if err != nil {
+		return false
+	}
+
+	canonicalNew, err := canonicalizeXML(new)
+	if err != nil {
+		return false
+	}
+
+	return canonicalOld == canonicalNew
+}
+
+// canonicalizeXML re-encodes doc with sorted attributes, normalized
+// namespaces, and whitespace/comments stripped, so two documents that are
+// semantically equivalent but textually different produce the same output.
+func canonicalizeXML(doc string) (string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(doc))
+
+	var out bytes.Buffer
+	encoder := xml.NewEncoder(&out)
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			// the decoder has already resolved element and attribute prefixes
+			// to their namespace URIs, so the xmlns declarations themselves
+			// are dropped: only the prefix they bind would differ
+			attrs := make([]xml.Attr, 0, len(t.Attr))
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+					continue
+				}
+				attrs = append(attrs, attr)
+			}
+			t.Attr = sortedAttrs(attrs)
+			if err := encoder.EncodeToken(t); err != nil {
+				return "", err
+			}
+		case xml.EndElement:
+			if err := encoder.EncodeToken(t); err != nil {
+				return "", err
+			}
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) == 0 {
+				continue
+			}
+			if err := encoder.EncodeToken(t); err != nil {
+				return "", err
+			}
+		case xml.Comment:
+			// comments carry no semantic meaning for libvirt's XML
+			continue
+		default:
+			if err := encoder.EncodeToken(token); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if err := encoder.Flush(); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+// sortedAttrs returns attrs sorted by their fully-qualified name, so two
+// elements with the same attributes in a different order compare equal.
+func sortedAttrs(attrs []xml.Attr) []xml.Attr {
+	sorted := make([]xml.Attr, len(attrs))
+	copy(sorted, attrs)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Name.Space != sorted[j].Name.Space {
+			return sorted[i].Name.Space < sorted[j].Name.Space
+		}
+		return sorted[i].Name.Local < sorted[j].Name.Local
+	})
+	return sorted
+}
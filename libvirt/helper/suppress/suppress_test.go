@@ -0,0 +1,51 @@
+package suppress
+
+import "testing"
+
+func TestXMLEquivalent(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want bool
+	}{
+		{
+			name: "identical",
+			old:  `<pool type="dir"><name>p</name></pool>`,
+			new:  `<pool type="dir"><name>p</name></pool>`,
+			want: true,
+		},
+		{
+			name: "attribute order differs",
+			old:  `<pool type="dir" foo="bar"></pool>`,
+			new:  `<pool foo="bar" type="dir"></pool>`,
+			want: true,
+		},
+		{
+			name: "whitespace and comments differ",
+			old:  "<pool type=\"dir\">\n  <!-- comment -->\n  <name>p</name>\n</pool>",
+			new:  `<pool type="dir"><name>p</name></pool>`,
+			want: true,
+		},
+		{
+			name: "real difference",
+			old:  `<pool type="dir"><name>p</name></pool>`,
+			new:  `<pool type="dir"><name>q</name></pool>`,
+			want: false,
+		},
+		{
+			name: "not well-formed",
+			old:  `<pool>`,
+			new:  `<pool></pool>`,
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := XMLEquivalent("xml.0.xslt", tt.old, tt.new, nil); got != tt.want {
+				t.Errorf("XMLEquivalent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,9 @@ package libvirt
 import (
 	"encoding/xml"
 	"fmt"
+	"net"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	libvirt "github.com/libvirt/libvirt-go"
 	libvirtxml "github.com/libvirt/libvirt-go-xml"
 )
@@ -31,3 +34,38 @@ func newDefPoolFromXML(s string) (libvirtxml.StoragePool, error) {
 	}
 	return poolDef, nil
 }
+
+// splitHostPort splits a "host:port" string as used in the "source_host"
+// attribute of rbd pools. host may be a hostname, an IPv4 literal, or
+// a bracketed IPv6 literal (e.g. "[::1]:6789"); a bare IPv6 literal with no
+// port (e.g. "::1") is returned as-is since it can't be told apart from
+// "host:port" otherwise. If no port is given, it returns an empty port
+// string so the <host/> element is generated without a port attribute.
+func splitHostPort(hostPort string) (host string, port string) {
+	if h, p, err := net.SplitHostPort(hostPort); err == nil {
+		return h, p
+	}
+	return hostPort, ""
+}
+
+// poolAuthFromResourceData builds a libvirtxml.StoragePoolSourceAuth from the
+// "auth" block of a libvirt_pool resource, or returns nil if none was given.
+func poolAuthFromResourceData(d *schema.ResourceData, authType string) (*libvirtxml.StoragePoolSourceAuth, error) {
+	if d.Get("auth.#").(int) == 0 {
+		return nil, nil
+	}
+
+	username := d.Get("auth.0.username").(string)
+	secretUUID := d.Get("auth.0.secret_uuid").(string)
+	if username == "" || secretUUID == "" {
+		return nil, fmt.Errorf("\"auth\" block requires both \"username\" and \"secret_uuid\"")
+	}
+
+	return &libvirtxml.StoragePoolSourceAuth{
+		Type:     authType,
+		Username: username,
+		Secret: &libvirtxml.StoragePoolSourceAuthSecret{
+			UUID: secretUUID,
+		},
+	}, nil
+}
@@ -0,0 +1,72 @@
+package libvirt
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestSplitHostPort(t *testing.T) {
+	tests := []struct {
+		in       string
+		wantHost string
+		wantPort string
+	}{
+		{"ceph-mon1.example.com", "ceph-mon1.example.com", ""},
+		{"ceph-mon1.example.com:6789", "ceph-mon1.example.com", "6789"},
+		{"192.168.1.1:3260", "192.168.1.1", "3260"},
+		{"[::1]:6789", "::1", "6789"},
+		{"::1", "::1", ""},
+	}
+
+	for _, tt := range tests {
+		host, port := splitHostPort(tt.in)
+		if host != tt.wantHost || port != tt.wantPort {
+			t.Errorf("splitHostPort(%q) = (%q, %q), want (%q, %q)", tt.in, host, port, tt.wantHost, tt.wantPort)
+		}
+	}
+}
+
+func TestPoolAuthFromResourceData(t *testing.T) {
+	poolSchema := resourceLibvirtPool().Schema
+
+	t.Run("no auth block", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, poolSchema, map[string]interface{}{})
+
+		auth, err := poolAuthFromResourceData(d, "ceph")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if auth != nil {
+			t.Fatalf("expected nil auth, got %+v", auth)
+		}
+	})
+
+	t.Run("auth block set", func(t *testing.T) {
+		d := schema.TestResourceDataRaw(t, poolSchema, map[string]interface{}{
+			"auth": []interface{}{
+				map[string]interface{}{
+					"username":    "admin",
+					"secret_uuid": "11111111-1111-1111-1111-111111111111",
+				},
+			},
+		})
+
+		auth, err := poolAuthFromResourceData(d, "ceph")
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if auth == nil {
+			t.Fatal("expected non-nil auth")
+		}
+		if auth.Type != "ceph" {
+			t.Errorf("auth.Type = %q, want %q", auth.Type, "ceph")
+		}
+		if auth.Username != "admin" {
+			t.Errorf("auth.Username = %q, want %q", auth.Username, "admin")
+		}
+		if auth.Secret == nil || auth.Secret.UUID != "11111111-1111-1111-1111-111111111111" {
+			t.Errorf("auth.Secret = %+v, want UUID 11111111-1111-1111-1111-111111111111", auth.Secret)
+		}
+	})
+}
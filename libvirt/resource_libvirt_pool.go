@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/dedis/terraform-provider-libvirt/libvirt/helper/suppress"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	libvirt "github.com/libvirt/libvirt-go"
 	libvirtxml "github.com/libvirt/libvirt-go-xml"
@@ -45,6 +46,16 @@ func resourceLibvirtPool() *schema.Resource {
 				Optional: true,
 				ForceNew: true,
 			},
+			// target_path exposes the pool's target path so other
+			// resources/configs can reference it instead of hard-coding
+			// "/var/lib/libvirt/images". Resolving a volume's path from
+			// pool_name + volume_name is not implemented anywhere in this
+			// tree: it requires changes in resource_libvirt_domain.go and
+			// resource_libvirt_volume.go, neither of which exist here.
+			"target_path": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 			"xml": {
 				Type:     schema.TypeList,
 				Optional: true,
@@ -53,13 +64,23 @@ func resourceLibvirtPool() *schema.Resource {
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"xslt": {
-							Type:     schema.TypeString,
-							Optional: true,
-							ForceNew: true,
+							Type:             schema.TypeString,
+							Optional:         true,
+							ForceNew:         true,
+							DiffSuppressFunc: suppress.XMLEquivalent,
 						},
 					},
 				},
 			},
+			// rendered_xml is the pool's XML as returned by libvirt once created,
+			// after defaults and any xml.xslt transform have been applied. It
+			// doesn't need suppress.XMLEquivalent as a DiffSuppressFunc: being
+			// Computed-only, it's never part of a user-supplied config, so
+			// Terraform never computes a diff against it to suppress.
+			"rendered_xml": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
 
 			// Dir-specific attributes
 			"path": {
@@ -77,6 +98,96 @@ func resourceLibvirtPool() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
+
+			// netfs/iscsi/rbd-specific attributes
+			"source_host": {
+				Type:     schema.TypeList,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"source_path": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"source_format": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"source_device": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"source_initiator_iqn": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"source_name": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+			"auth": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				ForceNew: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"username": {
+							Type:     schema.TypeString,
+							Required: true,
+							ForceNew: true,
+						},
+						"secret_uuid": {
+							Type:     schema.TypeString,
+							Required: true,
+							ForceNew: true,
+						},
+					},
+				},
+			},
+
+			// upload populates a volume in the pool by streaming a local file to
+			// libvirtd right after the pool is created.
+			"upload": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				ForceNew: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"volume_name": {
+							Type:     schema.TypeString,
+							Required: true,
+							ForceNew: true,
+						},
+						"source": {
+							Type:     schema.TypeString,
+							Required: true,
+							ForceNew: true,
+						},
+						"sparse": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+							ForceNew: true,
+						},
+						"chunk_size": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "4MiB",
+							ForceNew: true,
+						},
+					},
+				},
+			},
 		},
 		Importer: &schema.ResourceImporter{
 			State: schema.ImportStatePassthrough,
@@ -91,8 +202,10 @@ func resourceLibvirtPoolCreate(d *schema.ResourceData, meta interface{}) error {
 	}
 
 	poolType := d.Get("type").(string)
-	if poolType != "dir" && poolType != "logical" {
-		return fmt.Errorf("Only storage pools of type \"dir\" and \"logical\" are supported")
+	switch poolType {
+	case "dir", "logical", "netfs", "iscsi", "rbd":
+	default:
+		return fmt.Errorf("Only storage pools of type \"dir\", \"logical\", \"netfs\", \"iscsi\", and \"rbd\" are supported")
 	}
 
 	poolName := d.Get("name").(string)
@@ -156,6 +269,123 @@ func resourceLibvirtPoolCreate(d *schema.ResourceData, meta interface{}) error {
 			// if no source device given for logical pool, we don't need to build, just use the existing vg
 			needToBuild = false
 		}
+	} else if poolType == "netfs" {
+		sourceHost := d.Get("source_host.#").(int)
+		if sourceHost != 1 {
+			return fmt.Errorf("\"source_host\" attribute must have a single entry for storage pools of type \"netfs\"")
+		}
+
+		sourcePath := d.Get("source_path").(string)
+		if sourcePath == "" {
+			return fmt.Errorf("\"source_path\" attribute is required for storage pools of type \"netfs\"")
+		}
+
+		if poolPath == "" {
+			return fmt.Errorf("\"path\" attribute is required for storage pools of type \"netfs\"")
+		}
+
+		poolDef = &libvirtxml.StoragePool{
+			Type: "netfs",
+			Name: poolName,
+			Target: &libvirtxml.StoragePoolTarget{
+				Path: poolPath,
+			},
+			Source: &libvirtxml.StoragePoolSource{
+				Host: []libvirtxml.StoragePoolSourceHost{
+					{Name: d.Get("source_host.0").(string)},
+				},
+				Dir: &libvirtxml.StoragePoolSourceDir{
+					Path: sourcePath,
+				},
+			},
+		}
+
+		if sourceFormat := d.Get("source_format").(string); sourceFormat != "" {
+			poolDef.Source.Format = &libvirtxml.StoragePoolSourceFormat{
+				Type: sourceFormat,
+			}
+		}
+
+		// the mount point is expected to already exist, so there is nothing to build
+		needToBuild = false
+	} else if poolType == "iscsi" {
+		sourceHost := d.Get("source_host.#").(int)
+		if sourceHost != 1 {
+			return fmt.Errorf("\"source_host\" attribute must have a single entry for storage pools of type \"iscsi\"")
+		}
+
+		sourceDevice := d.Get("source_device").(string)
+		if sourceDevice == "" {
+			return fmt.Errorf("\"source_device\" attribute (the target IQN) is required for storage pools of type \"iscsi\"")
+		}
+
+		poolDef = &libvirtxml.StoragePool{
+			Type: "iscsi",
+			Name: poolName,
+			Target: &libvirtxml.StoragePoolTarget{
+				Path: poolPath,
+			},
+			Source: &libvirtxml.StoragePoolSource{
+				Host: []libvirtxml.StoragePoolSourceHost{
+					{Name: d.Get("source_host.0").(string)},
+				},
+				Device: []libvirtxml.StoragePoolSourceDevice{
+					{Path: sourceDevice},
+				},
+			},
+		}
+
+		if initiatorIQN := d.Get("source_initiator_iqn").(string); initiatorIQN != "" {
+			poolDef.Source.Initiator = &libvirtxml.StoragePoolSourceInitiator{
+				IQN: &libvirtxml.StoragePoolSourceInitiatorIQN{
+					Name: initiatorIQN,
+				},
+			}
+		}
+
+		if auth, err := poolAuthFromResourceData(d, "chap"); err != nil {
+			return err
+		} else if auth != nil {
+			poolDef.Source.Auth = auth
+		}
+
+		// iscsi targets are expected to already exist on the iscsi server, nothing to build
+		needToBuild = false
+	} else if poolType == "rbd" {
+		sourceHost := d.Get("source_host.#").(int)
+		if sourceHost == 0 {
+			return fmt.Errorf("\"source_host\" attribute requires at least one entry for storage pools of type \"rbd\"")
+		}
+
+		sourceName := d.Get("source_name").(string)
+		if sourceName == "" {
+			return fmt.Errorf("\"source_name\" attribute (the ceph pool name) is required for storage pools of type \"rbd\"")
+		}
+
+		var hosts []libvirtxml.StoragePoolSourceHost
+		for i := 0; i < sourceHost; i++ {
+			hostPort := d.Get(fmt.Sprintf("source_host.%d", i)).(string)
+			host, port := splitHostPort(hostPort)
+			hosts = append(hosts, libvirtxml.StoragePoolSourceHost{Name: host, Port: port})
+		}
+
+		poolDef = &libvirtxml.StoragePool{
+			Type: "rbd",
+			Name: poolName,
+			Source: &libvirtxml.StoragePoolSource{
+				Name: sourceName,
+				Host: hosts,
+			},
+		}
+
+		if auth, err := poolAuthFromResourceData(d, "ceph"); err != nil {
+			return err
+		} else if auth != nil {
+			poolDef.Source.Auth = auth
+		}
+
+		// rbd pools are not built, libvirt talks to the running ceph cluster directly
+		needToBuild = false
 	}
 
 	data, err := xmlMarshallIndented(poolDef)
@@ -198,6 +428,12 @@ func resourceLibvirtPoolCreate(d *schema.ResourceData, meta interface{}) error {
 		return fmt.Errorf("Error refreshing libvirt storage pool: %s", err)
 	}
 
+	if d.Get("upload.#").(int) == 1 {
+		if err := uploadVolumeToPool(client, pool, d); err != nil {
+			return err
+		}
+	}
+
 	id, err := pool.GetUUIDString()
 	if err != nil {
 		return fmt.Errorf("Error retrieving libvirt pool id: %s", err)
@@ -252,6 +488,7 @@ func resourceLibvirtPoolRead(d *schema.ResourceData, meta interface{}) error {
 	if err != nil {
 		return fmt.Errorf("could not get XML description for pool %s: %s", poolName, err)
 	}
+	d.Set("rendered_xml", poolDefXML)
 
 	var poolDef libvirtxml.StoragePool
 	err = xml.Unmarshal([]byte(poolDefXML), &poolDef)
@@ -273,6 +510,7 @@ func resourceLibvirtPoolRead(d *schema.ResourceData, meta interface{}) error {
 			d.Set("path", poolPath)
 		}
 	}
+	d.Set("target_path", poolPath)
 
 	poolType := poolDef.Type
 	if poolType == "" {
@@ -282,6 +520,50 @@ func resourceLibvirtPoolRead(d *schema.ResourceData, meta interface{}) error {
 		d.Set("type", poolType)
 	}
 
+	if poolDef.Source != nil {
+		if len(poolDef.Source.Host) > 0 {
+			hosts := make([]string, 0, len(poolDef.Source.Host))
+			for _, host := range poolDef.Source.Host {
+				if host.Port != "" {
+					hosts = append(hosts, fmt.Sprintf("%s:%s", host.Name, host.Port))
+				} else {
+					hosts = append(hosts, host.Name)
+				}
+			}
+			d.Set("source_host", hosts)
+		}
+
+		if poolDef.Source.Dir != nil {
+			d.Set("source_path", poolDef.Source.Dir.Path)
+		}
+
+		if poolDef.Source.Format != nil {
+			d.Set("source_format", poolDef.Source.Format.Type)
+		}
+
+		if len(poolDef.Source.Device) > 0 && poolType == "iscsi" {
+			d.Set("source_device", poolDef.Source.Device[0].Path)
+		}
+
+		if poolDef.Source.Initiator != nil && poolDef.Source.Initiator.IQN != nil {
+			d.Set("source_initiator_iqn", poolDef.Source.Initiator.IQN.Name)
+		}
+
+		if poolType == "rbd" {
+			d.Set("source_name", poolDef.Source.Name)
+		}
+
+		if poolDef.Source.Auth != nil {
+			auth := map[string]interface{}{
+				"username": poolDef.Source.Auth.Username,
+			}
+			if poolDef.Source.Auth.Secret != nil {
+				auth["secret_uuid"] = poolDef.Source.Auth.Secret.UUID
+			}
+			d.Set("auth", []map[string]interface{}{auth})
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,157 @@
+package libvirt
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/dustin/go-humanize"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	libvirt "github.com/libvirt/libvirt-go"
+	libvirtxml "github.com/libvirt/libvirt-go-xml"
+)
+
+// logUploadProgressEveryNChunks controls how often uploadVolumeToPool emits a
+// [DEBUG] progress line while streaming a volume, so large uploads don't
+// flood the log with one line per chunk.
+const logUploadProgressEveryNChunks = 16
+
+// uploadVolumeToPool defines a new storage volume in pool from the "upload"
+// block of the libvirt_pool resource, then streams its "source" file into
+// that volume using the libvirt stream API.
+func uploadVolumeToPool(client *Client, pool *libvirt.StoragePool, d *schema.ResourceData) error {
+	volumeName := d.Get("upload.0.volume_name").(string)
+	source := d.Get("upload.0.source").(string)
+	sparse := d.Get("upload.0.sparse").(bool)
+
+	chunkSize, err := humanize.ParseBytes(d.Get("upload.0.chunk_size").(string))
+	if err != nil {
+		return fmt.Errorf("could not parse \"upload.chunk_size\": %s", err)
+	}
+	if chunkSize == 0 {
+		return fmt.Errorf("\"upload.chunk_size\" must be greater than zero")
+	}
+
+	file, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("error opening upload source %q: %s", source, err)
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("error reading upload source %q: %s", source, err)
+	}
+	size := uint64(stat.Size())
+
+	volDef := libvirtxml.StorageVolume{
+		Name:       volumeName,
+		Capacity:   &libvirtxml.StorageVolumeSize{Value: size},
+		Allocation: &libvirtxml.StorageVolumeSize{Value: size},
+		Target: &libvirtxml.StorageVolumeTarget{
+			Format: &libvirtxml.StorageVolumeTargetFormat{Type: "raw"},
+		},
+	}
+
+	volData, err := xmlMarshallIndented(volDef)
+	if err != nil {
+		return fmt.Errorf("error serializing libvirt storage volume: %s", err)
+	}
+
+	vol, err := pool.StorageVolCreateXML(volData, 0)
+	if err != nil {
+		return fmt.Errorf("error creating volume %q for upload: %s", volumeName, err)
+	}
+	defer vol.Free()
+
+	// A blocking stream is used here (rather than STREAM_NONBLOCK) so Send
+	// can just be called in a loop: a non-blocking stream requires an event
+	// callback to resume on EAGAIN, which isn't worth the complexity for a
+	// one-shot upload that's expected to run synchronously.
+	stream, err := client.libvirt.NewStream(0)
+	if err != nil {
+		return fmt.Errorf("error creating upload stream: %s", err)
+	}
+	defer stream.Free()
+
+	var uploadFlags libvirt.StorageVolUploadFlags
+	if sparse {
+		uploadFlags = libvirt.STORAGE_VOL_UPLOAD_SPARSE_STREAM
+	}
+
+	if err := vol.Upload(stream, 0, size, uploadFlags); err != nil {
+		return fmt.Errorf("error starting upload of %q to volume %q: %s", source, volumeName, err)
+	}
+
+	transferredBytes, uploadErr := streamVolume(stream, file, size, chunkSize, sparse)
+	if uploadErr != nil {
+		_ = stream.Abort()
+		return fmt.Errorf("error uploading %q to volume %q: %s", source, volumeName, uploadErr)
+	}
+
+	if transferredBytes < size {
+		_ = stream.Abort()
+		return fmt.Errorf("short write uploading %q to volume %q: transferred %d of %d bytes", source, volumeName, transferredBytes, size)
+	}
+
+	if err := stream.Finish(); err != nil {
+		return fmt.Errorf("error finishing upload of %q to volume %q: %s", source, volumeName, err)
+	}
+
+	return nil
+}
+
+// streamVolume reads file in chunkSize-sized buffers and sends them to
+// stream, logging progress every logUploadProgressEveryNChunks chunks. It
+// returns the number of bytes successfully transferred.
+func streamVolume(stream *libvirt.Stream, file *os.File, size, chunkSize uint64, sparse bool) (uint64, error) {
+	buf := make([]byte, chunkSize)
+	var transferredBytes uint64
+	var chunk uint64
+
+	for {
+		n, readErr := file.Read(buf)
+		if n > 0 {
+			if sparse && isZeroChunk(buf[:n]) {
+				if err := stream.SendHole(int64(n), 0); err != nil {
+					return transferredBytes, fmt.Errorf("error sending sparse hole: %s", err)
+				}
+				transferredBytes += uint64(n)
+			} else {
+				sent, err := stream.Send(buf[:n])
+				if err != nil {
+					return transferredBytes, fmt.Errorf("error sending chunk: %s", err)
+				}
+				transferredBytes += uint64(sent)
+			}
+
+			chunk++
+			if chunk%logUploadProgressEveryNChunks == 0 {
+				log.Printf("[DEBUG] uploaded %d/%d bytes", transferredBytes, size)
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return transferredBytes, fmt.Errorf("error reading source file: %s", readErr)
+		}
+	}
+
+	log.Printf("[DEBUG] uploaded %d/%d bytes", transferredBytes, size)
+
+	return transferredBytes, nil
+}
+
+// isZeroChunk reports whether buf is entirely made up of zero bytes, so a
+// sparse upload can punch a hole instead of writing it out.
+func isZeroChunk(buf []byte) bool {
+	for _, b := range buf {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
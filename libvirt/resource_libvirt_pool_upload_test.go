@@ -0,0 +1,24 @@
+package libvirt
+
+import "testing"
+
+func TestIsZeroChunk(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  []byte
+		want bool
+	}{
+		{"empty", []byte{}, true},
+		{"all zero", make([]byte, 16), true},
+		{"leading non-zero", []byte{1, 0, 0, 0}, false},
+		{"trailing non-zero", []byte{0, 0, 0, 1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isZeroChunk(tt.buf); got != tt.want {
+				t.Errorf("isZeroChunk(%v) = %v, want %v", tt.buf, got, tt.want)
+			}
+		})
+	}
+}